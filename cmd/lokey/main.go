@@ -0,0 +1,206 @@
+// Command lokey is the operator CLI for the entropy service. It carries
+// schema administration (including encryption-at-rest key rotation via
+// db rekey) and can also run the HTTP/WebSocket server that exposes
+// pkg/api's subscription endpoint.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/psmgeelen/Lokey/pkg/api"
+	"github.com/psmgeelen/Lokey/pkg/database"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "lokey:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: lokey <db|serve> ...")
+	}
+
+	switch args[0] {
+	case "db":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: lokey db <migrate|rollback|rekey> --db-path=<path> ...")
+		}
+		switch args[1] {
+		case "migrate":
+			return runMigrate(args[2:], true)
+		case "rollback":
+			return runMigrate(args[2:], false)
+		case "rekey":
+			return runRekey(args[2:])
+		default:
+			return fmt.Errorf("unknown db subcommand %q", args[1])
+		}
+	case "serve":
+		return runServe(args[1:])
+	default:
+		return fmt.Errorf("usage: lokey <db|serve> ...")
+	}
+}
+
+// keyProviderFromFlags builds a database.KeyProvider from a pair of
+// "--foo-env"/"--foo-file" flags, e.g. --encryption-key-env and
+// --encryption-key-file. At most one may be set; it returns (nil, nil) if
+// neither is, so callers can treat that as "encryption not requested".
+func keyProviderFromFlags(flagPrefix, keyEnv, keyFile string) (database.KeyProvider, error) {
+	if keyEnv != "" && keyFile != "" {
+		return nil, fmt.Errorf("only one of --%s-env or --%s-file may be set", flagPrefix, flagPrefix)
+	}
+	switch {
+	case keyEnv != "":
+		return database.EnvKeyProvider{VarName: keyEnv}, nil
+	case keyFile != "":
+		return database.FileKeyProvider{Path: keyFile}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func runMigrate(args []string, up bool) error {
+	fs := flag.NewFlagSet("db migrate", flag.ExitOnError)
+	dbPath := fs.String("db-path", "lokey.db", "path to the DuckDB database file")
+	version := fs.Int("version", database.LatestMigrationVersion, "target schema version (defaults to latest for migrate, required for rollback)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	versionSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "version" {
+			versionSet = true
+		}
+	})
+
+	if !up && *version == database.LatestMigrationVersion {
+		return fmt.Errorf("--version is required for rollback")
+	}
+
+	handler, err := database.NewDuckDBHandler(*dbPath, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *dbPath, err)
+	}
+	defer handler.Close()
+
+	// NewDuckDBHandler already migrated the schema forward to latest on
+	// open, so an explicit "migrate --version=N" lower than what's already
+	// applied would otherwise be indistinguishable from a rollback. Refuse
+	// it instead of silently dropping columns/tables the operator didn't
+	// ask to remove.
+	if up && versionSet {
+		current, err := handler.CurrentMigrationVersion(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to read current schema version: %w", err)
+		}
+		if *version < current {
+			return fmt.Errorf("refusing to roll back schema via migrate (current version %d > requested %d); use the rollback subcommand instead", current, *version)
+		}
+	}
+
+	if err := handler.Migrate(context.Background(), *version); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	finalVersion, err := handler.CurrentMigrationVersion(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to read current schema version: %w", err)
+	}
+
+	fmt.Printf("lokey: schema at %s now at version %d\n", *dbPath, finalVersion)
+	return nil
+}
+
+func runRekey(args []string) error {
+	fs := flag.NewFlagSet("db rekey", flag.ExitOnError)
+	dbPath := fs.String("db-path", "lokey.db", "path to the DuckDB database file")
+	currentKeyEnv := fs.String("encryption-key-env", "", "environment variable holding the current hex-encoded master key")
+	currentKeyFile := fs.String("encryption-key-file", "", "file holding the current hex-encoded master key")
+	newKeyEnv := fs.String("new-encryption-key-env", "", "environment variable holding the new hex-encoded master key")
+	newKeyFile := fs.String("new-encryption-key-file", "", "file holding the new hex-encoded master key")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	currentProvider, err := keyProviderFromFlags("encryption-key", *currentKeyEnv, *currentKeyFile)
+	if err != nil {
+		return err
+	}
+	if currentProvider == nil {
+		return fmt.Errorf("one of --encryption-key-env or --encryption-key-file is required")
+	}
+
+	newProvider, err := keyProviderFromFlags("new-encryption-key", *newKeyEnv, *newKeyFile)
+	if err != nil {
+		return err
+	}
+	if newProvider == nil {
+		return fmt.Errorf("one of --new-encryption-key-env or --new-encryption-key-file is required")
+	}
+
+	handler, err := database.NewDuckDBHandlerWithEncryption(*dbPath, 0, 0, currentProvider)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *dbPath, err)
+	}
+	defer handler.Close()
+
+	if err := handler.Rekey(newProvider); err != nil {
+		return fmt.Errorf("rekey failed: %w", err)
+	}
+
+	fmt.Printf("lokey: %s rekeyed successfully\n", *dbPath)
+	return nil
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	storeURL := fs.String("store-url", "duckdb://lokey.db", "store connection URL (duckdb://path, postgres://..., or sqlite://path)")
+	addr := fs.String("addr", ":8080", "address to listen on")
+	allowedOrigins := fs.String("allowed-origins", "", "comma-separated list of Origin header values allowed to open the /subscribe WebSocket")
+	encryptionKeyEnv := fs.String("encryption-key-env", "", "environment variable holding the hex-encoded master key for encryption-at-rest (DuckDB only)")
+	encryptionKeyFile := fs.String("encryption-key-file", "", "file holding the hex-encoded master key for encryption-at-rest (DuckDB only)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	keyProvider, err := keyProviderFromFlags("encryption-key", *encryptionKeyEnv, *encryptionKeyFile)
+	if err != nil {
+		return err
+	}
+
+	var store database.Store
+	if keyProvider != nil {
+		scheme, dbPath, ok := strings.Cut(*storeURL, "://")
+		if !ok || scheme != "duckdb" {
+			return fmt.Errorf("encryption-at-rest is only supported for duckdb:// stores")
+		}
+		store, err = database.NewDuckDBHandlerWithEncryption(dbPath, 0, 0, keyProvider)
+	} else {
+		store, err = database.NewStore(*storeURL, 0, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *storeURL, err)
+	}
+	defer store.Close()
+
+	var origins []string
+	if *allowedOrigins != "" {
+		origins = strings.Split(*allowedOrigins, ",")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/subscribe", api.NewSubscriptionHandler(store, origins...))
+
+	fmt.Printf("lokey: serving on %s\n", *addr)
+	return http.ListenAndServe(*addr, mux)
+}
@@ -0,0 +1,99 @@
+// Package api exposes HTTP endpoints for external consumers of the entropy
+// service, starting with a WebSocket fan-out of pool events.
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/psmgeelen/Lokey/pkg/database"
+)
+
+var baseUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// SubscriptionHandler upgrades incoming requests to a WebSocket and streams
+// matching database.Event notifications so downstream apps can react to
+// entropy availability without polling GetStats.
+type SubscriptionHandler struct {
+	store          database.Store
+	allowedOrigins map[string]bool
+}
+
+// NewSubscriptionHandler builds a SubscriptionHandler backed by store. This
+// streams internal entropy telemetry, so allowedOrigins must list the exact
+// Origin header values permitted to open the WebSocket (e.g.
+// "https://dashboard.internal"); a request with a non-empty Origin that
+// isn't on the list is rejected during the upgrade. Requests with no Origin
+// header at all (same-origin and non-browser clients) are always allowed,
+// since they can't be spoofed by a cross-site page.
+func NewSubscriptionHandler(store database.Store, allowedOrigins ...string) *SubscriptionHandler {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, o := range allowedOrigins {
+		origins[o] = true
+	}
+	return &SubscriptionHandler{store: store, allowedOrigins: origins}
+}
+
+// checkOrigin rejects cross-site WebSocket upgrades from origins not on the
+// configured allow-list.
+func (h *SubscriptionHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	return h.allowedOrigins[origin]
+}
+
+// ServeHTTP upgrades the connection and streams events until the client
+// disconnects or a write fails.
+func (h *SubscriptionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upgrader := baseUpgrader
+	upgrader.CheckOrigin = h.checkOrigin
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("subscription upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	events, cancel := h.store.Subscribe(filterFromQuery(r))
+	defer cancel()
+
+	// Reading is what surfaces the client's close frame or a dropped
+	// connection; cancelling the subscription then ends the loop below.
+	go func() {
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	for event := range events {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+}
+
+// filterFromQuery builds a SubscriptionFilter from query parameters:
+// ?source=hardware&event=stored&min_batch_size=8
+func filterFromQuery(r *http.Request) database.SubscriptionFilter {
+	q := r.URL.Query()
+
+	minBatchSize, _ := strconv.Atoi(q.Get("min_batch_size"))
+
+	return database.SubscriptionFilter{
+		Source:       database.EventSource(q.Get("source")),
+		EventType:    database.EventType(q.Get("event")),
+		MinBatchSize: minBatchSize,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/d2r2/go-i2c"
@@ -19,11 +20,109 @@ const (
 	WakeParameter   = 0x11 // Wake parameter
 )
 
+// NIST SP 800-90B continuous health test parameters. Both cutoffs assume a
+// conservative min-entropy estimate of H=6 bits/byte and a false-positive
+// rate of alpha=2^-30, per the standard's worked examples.
+const (
+	// repetitionCountCutoff is C = 1 + ceil(-log2(alpha)/H) for H=6, alpha=2^-30.
+	repetitionCountCutoff = 6
+
+	// adaptiveProportionWindow is the number of samples (W) inspected per
+	// Adaptive Proportion Test window.
+	adaptiveProportionWindow = 512
+
+	// adaptiveProportionCutoff is the binomial-CDF-derived cutoff for
+	// W=512, H=6, alpha=2^-30.
+	adaptiveProportionCutoff = 410
+)
+
 type Controller struct {
 	i2c         *i2c.I2C
 	initialWake bool
 	LastError   error
 	mockMode    bool
+
+	healthMu          sync.Mutex
+	rct               repetitionCountTest
+	apt               adaptiveProportionTest
+	stats             HealthStats
+	healthFailureHook HealthFailureHook
+
+	mockRandFunc func([]byte) error
+}
+
+// HealthFailureHook is invoked with the raw sample and its source
+// ("hardware" or "software") whenever a continuous health test trips. It
+// lets whatever wires this controller to a database.Store record the
+// failing sample via StoreTRNGHealthFailure for post-mortem, without this
+// package importing database (the same plain-callback pattern as
+// database.FuncKeyProvider).
+type HealthFailureHook func(sample []byte, source string)
+
+// repetitionCountTest implements the SP 800-90B Repetition Count Test: it
+// flags a failure if the same sample value repeats repetitionCountCutoff
+// times in a row.
+type repetitionCountTest struct {
+	hasSample  bool
+	lastSample byte
+	run        int
+}
+
+// check folds in the next sample and reports whether the test still passes.
+func (t *repetitionCountTest) check(sample byte) bool {
+	if !t.hasSample || sample != t.lastSample {
+		t.hasSample = true
+		t.lastSample = sample
+		t.run = 1
+		return true
+	}
+
+	t.run++
+	return t.run < repetitionCountCutoff
+}
+
+// adaptiveProportionTest implements the SP 800-90B Adaptive Proportion
+// Test: over consecutive windows of adaptiveProportionWindow samples it
+// counts occurrences of the sample that opened the window, and flags a
+// failure if that count exceeds adaptiveProportionCutoff. The windows do not
+// overlap: the test is evaluated once per window, not once per sample.
+type adaptiveProportionTest struct {
+	hasReference bool
+	reference    byte
+	count        int
+	seen         int
+}
+
+// check folds in the next sample and reports whether the test still passes.
+// A new window starts immediately after each evaluation.
+func (t *adaptiveProportionTest) check(sample byte) bool {
+	if !t.hasReference {
+		t.hasReference = true
+		t.reference = sample
+		t.count = 1
+		t.seen = 1
+		return true
+	}
+
+	t.seen++
+	if sample == t.reference {
+		t.count++
+	}
+
+	if t.seen < adaptiveProportionWindow {
+		return true
+	}
+
+	t.hasReference = false
+	return t.count <= adaptiveProportionCutoff
+}
+
+// HealthStats reports the running state of the continuous health tests.
+type HealthStats struct {
+	RepetitionCountFailures    int
+	AdaptiveProportionFailures int
+	LastFailure                time.Time
+	Healthy                    bool
 }
 
 // NewController creates a new ATECC608A controller
@@ -44,6 +143,7 @@ func NewController(i2cBusNumber int) (*Controller, error) {
 		initialWake: false,
 		LastError:   nil,
 		mockMode:    mockMode,
+		stats:       HealthStats{Healthy: true},
 	}
 
 	// In real mode, wake up the device on initialization
@@ -73,6 +173,7 @@ func NewControllerWithMockMode(i2cBusNumber int, forceMock bool) (*Controller, e
 			initialWake: false,
 			LastError:   nil,
 			mockMode:    true,
+			stats:       HealthStats{Healthy: true},
 		}
 		log.Println("ATECC608A controller running in FORCED MOCK MODE. Using software-generated pseudo-random numbers.")
 		return controller, nil
@@ -127,15 +228,29 @@ func (c *Controller) readResponse(length int) ([]byte, error) {
 	return buf, nil
 }
 
-// GenerateRandomBytes generates random bytes using the ATECC608A's TRNG or software PRNG in mock mode
+// GenerateRandomBytes generates random bytes using the ATECC608A's TRNG or
+// software PRNG in mock mode. It returns an error instead of the sample if
+// the Repetition Count or Adaptive Proportion Test trips, so a stuck-at
+// fault can't flow into the entropy pool through the return value; callers
+// that need the failing sample for audit purposes should use
+// SetHealthFailureHook instead of retrying on error.
 func (c *Controller) GenerateRandomBytes() ([]byte, error) {
 	// Use software random generation in mock mode
 	if c.mockMode {
 		randomData := make([]byte, 32)
-		_, err := rand.Read(randomData)
-		if err != nil {
+		randFunc := c.mockRandFunc
+		if randFunc == nil {
+			randFunc = func(b []byte) error {
+				_, err := rand.Read(b)
+				return err
+			}
+		}
+		if err := randFunc(randomData); err != nil {
 			return nil, fmt.Errorf("failed to generate software random data: %w", err)
 		}
+		if c.runHealthTests(randomData) {
+			return nil, errors.New("continuous health test tripped, discarding sample")
+		}
 		return randomData, nil
 	}
 
@@ -167,9 +282,94 @@ func (c *Controller) GenerateRandomBytes() ([]byte, error) {
 		return nil, errors.New("invalid random data length")
 	}
 
+	if c.runHealthTests(randomData) {
+		return nil, errors.New("continuous health test tripped, discarding sample")
+	}
 	return randomData, nil
 }
 
+// SetHealthFailureHook registers the callback runHealthTests invokes when a
+// continuous health test trips. Passing nil disables the hook.
+func (c *Controller) SetHealthFailureHook(hook HealthFailureHook) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	c.healthFailureHook = hook
+}
+
+// SetMockRandomSource overrides the byte source GenerateRandomBytes uses in
+// mock mode, replacing crypto/rand.Read. It has no effect outside mock
+// mode. Tests use this to feed deterministic (including intentionally bad,
+// e.g. all-zero) sequences through GenerateRandomBytes so the Repetition
+// Count and Adaptive Proportion Tests can be exercised without real
+// hardware. Passing nil restores crypto/rand.Read.
+func (c *Controller) SetMockRandomSource(source func([]byte) error) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	c.mockRandFunc = source
+}
+
+// runHealthTests folds each byte of a freshly generated sample through the
+// Repetition Count Test and Adaptive Proportion Test, reporting whether
+// either tripped. A failure marks the controller unhealthy until
+// ResetHealth is called, so a stuck-at fault can't silently poison the
+// entropy pool, and (if a hook is registered) reports the full failing
+// sample for post-mortem storage.
+func (c *Controller) runHealthTests(sample []byte) bool {
+	c.healthMu.Lock()
+
+	failed := false
+	for _, b := range sample {
+		if !c.rct.check(b) {
+			c.stats.RepetitionCountFailures++
+			c.stats.LastFailure = time.Now()
+			c.stats.Healthy = false
+			failed = true
+			log.Printf("ATECC608A health test failure: Repetition Count Test tripped")
+		}
+		if !c.apt.check(b) {
+			c.stats.AdaptiveProportionFailures++
+			c.stats.LastFailure = time.Now()
+			c.stats.Healthy = false
+			failed = true
+			log.Printf("ATECC608A health test failure: Adaptive Proportion Test tripped")
+		}
+	}
+
+	hook := c.healthFailureHook
+	mockMode := c.mockMode
+	c.healthMu.Unlock()
+
+	if failed && hook != nil {
+		source := "hardware"
+		if mockMode {
+			source = "software"
+		}
+		hook(sample, source)
+	}
+
+	return failed
+}
+
+// HealthStats returns the failure counters for the continuous health tests,
+// the time of the last failure, and whether the controller is presently
+// healthy. No count of passing samples or windows is kept.
+func (c *Controller) HealthStats() HealthStats {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	return c.stats
+}
+
+// ResetHealth clears a failed health state and restarts the continuous
+// tests from a clean window. It does not reset the failure counters, which
+// remain as a running audit trail.
+func (c *Controller) ResetHealth() {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	c.rct = repetitionCountTest{}
+	c.apt = adaptiveProportionTest{}
+	c.stats.Healthy = true
+}
+
 // GenerateHashFromRandom generates a SHA-256 hash of random data using the device's hardware or software in mock mode
 func (c *Controller) GenerateHashFromRandom() ([]byte, error) {
 	// Generate random data first
@@ -226,7 +426,14 @@ func (c *Controller) Close() error {
 
 // HealthCheck checks if the ATECC608A device is responsive
 func (c *Controller) HealthCheck() bool {
-	// Always healthy in mock mode
+	// A prior continuous health test failure holds the controller unhealthy
+	// until an operator calls ResetHealth, regardless of mode.
+	if !c.HealthStats().Healthy {
+		return false
+	}
+
+	// Mock mode has no device to wake or talk to; the continuous tests
+	// above are the only thing that can fail it.
 	if c.mockMode {
 		return true
 	}
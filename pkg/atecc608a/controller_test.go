@@ -0,0 +1,129 @@
+package atecc608a
+
+import (
+	"testing"
+)
+
+// zeros writes an all-zero sequence, which trips the Repetition Count Test
+// (and eventually the Adaptive Proportion Test) almost immediately.
+func zeros(b []byte) error {
+	for i := range b {
+		b[i] = 0
+	}
+	return nil
+}
+
+func newMockController(t *testing.T) *Controller {
+	t.Helper()
+	c, err := NewControllerWithMockMode(0, true)
+	if err != nil {
+		t.Fatalf("NewControllerWithMockMode failed: %v", err)
+	}
+	return c
+}
+
+func TestGenerateRandomBytesHealthy(t *testing.T) {
+	c := newMockController(t)
+
+	if _, err := c.GenerateRandomBytes(); err != nil {
+		t.Fatalf("GenerateRandomBytes failed: %v", err)
+	}
+
+	stats := c.HealthStats()
+	if !stats.Healthy {
+		t.Fatalf("expected controller to stay healthy on real random data, got %+v", stats)
+	}
+}
+
+func TestRepetitionCountTestTripsOnAllZeros(t *testing.T) {
+	c := newMockController(t)
+	c.SetMockRandomSource(zeros)
+
+	if _, err := c.GenerateRandomBytes(); err == nil {
+		t.Fatal("expected GenerateRandomBytes to return an error for an all-zero sample")
+	}
+
+	stats := c.HealthStats()
+	if stats.Healthy {
+		t.Fatal("expected controller to be unhealthy after an all-zero sample")
+	}
+	if stats.RepetitionCountFailures == 0 {
+		t.Fatal("expected at least one Repetition Count Test failure")
+	}
+	if c.HealthCheck() {
+		t.Fatal("HealthCheck should reflect the continuous test failure")
+	}
+}
+
+func TestHealthFailureHookReceivesFailingSample(t *testing.T) {
+	c := newMockController(t)
+	c.SetMockRandomSource(zeros)
+
+	var gotSample []byte
+	var gotSource string
+	c.SetHealthFailureHook(func(sample []byte, source string) {
+		gotSample = sample
+		gotSource = source
+	})
+
+	sample, err := c.GenerateRandomBytes()
+	if err == nil {
+		t.Fatal("expected GenerateRandomBytes to return an error for an all-zero sample")
+	}
+	if sample != nil {
+		t.Fatalf("expected a nil sample on health test failure, got %v", sample)
+	}
+
+	if gotSample == nil {
+		t.Fatal("expected health failure hook to be called")
+	}
+	if len(gotSample) != 32 {
+		t.Fatalf("hook received a %d-byte sample, want 32", len(gotSample))
+	}
+	if gotSource != "software" {
+		t.Fatalf("hook source = %q, want %q for a mock-mode controller", gotSource, "software")
+	}
+}
+
+func TestResetHealthClearsFailureButKeepsCounters(t *testing.T) {
+	c := newMockController(t)
+	c.SetMockRandomSource(zeros)
+
+	if _, err := c.GenerateRandomBytes(); err == nil {
+		t.Fatal("expected GenerateRandomBytes to return an error for an all-zero sample")
+	}
+	if c.HealthStats().Healthy {
+		t.Fatal("expected controller to be unhealthy before ResetHealth")
+	}
+
+	failuresBefore := c.HealthStats().RepetitionCountFailures
+
+	c.ResetHealth()
+
+	stats := c.HealthStats()
+	if !stats.Healthy {
+		t.Fatal("expected ResetHealth to clear the unhealthy state")
+	}
+	if stats.RepetitionCountFailures != failuresBefore {
+		t.Fatalf("ResetHealth should not reset failure counters, got %d want %d", stats.RepetitionCountFailures, failuresBefore)
+	}
+}
+
+func TestHealthCheckFailsUntilReset(t *testing.T) {
+	c := newMockController(t)
+	c.SetMockRandomSource(zeros)
+
+	if _, err := c.GenerateRandomBytes(); err == nil {
+		t.Fatal("expected GenerateRandomBytes to return an error for an all-zero sample")
+	}
+	if c.HealthCheck() {
+		t.Fatal("expected HealthCheck to fail after a tripped continuous test")
+	}
+
+	c.ResetHealth()
+	c.SetMockRandomSource(nil)
+
+	if !c.HealthCheck() {
+		t.Fatal("expected HealthCheck to recover after ResetHealth")
+	}
+}
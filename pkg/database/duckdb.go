@@ -1,12 +1,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"log"
 	"math"
 	"sync"
+	"time"
 
 	_ "github.com/marcboeker/go-duckdb"
 )
@@ -16,6 +18,12 @@ type DuckDBHandler struct {
 	trngQueueSize    int
 	fortunaQueueSize int
 	mutex            sync.Mutex
+	events           eventHub
+
+	trngInsertStmt    *sql.Stmt
+	fortunaInsertStmt *sql.Stmt
+
+	encryptor *Encryptor
 }
 
 // NewDuckDBHandler creates a new DuckDB database handler
@@ -32,76 +40,267 @@ func NewDuckDBHandler(dbPath string, trngQueueSize, fortunaQueueSize int) (*Duck
 		mutex:            sync.Mutex{},
 	}
 
-	err = handler.setupTables()
+	if err := handler.Migrate(context.Background(), LatestMigrationVersion); err != nil {
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	if err := handler.configurePragmas(); err != nil {
+		return nil, err
+	}
+
+	if err := handler.prepareStatements(); err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+// NewDuckDBHandlerWithEncryption creates a DuckDB database handler that
+// transparently encrypts the trng_data.hash and fortuna_data.data columns
+// with AES-256-GCM keys derived from keyProvider's master key.
+func NewDuckDBHandlerWithEncryption(dbPath string, trngQueueSize, fortunaQueueSize int, keyProvider KeyProvider) (*DuckDBHandler, error) {
+	handler, err := NewDuckDBHandler(dbPath, trngQueueSize, fortunaQueueSize)
 	if err != nil {
 		return nil, err
 	}
 
+	encryptor, err := newEncryptor(keyProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize encryption: %w", err)
+	}
+	handler.encryptor = encryptor
+
 	return handler, nil
 }
 
-// setupTables creates necessary tables if they don't exist
-func (d *DuckDBHandler) setupTables() error {
-	// Create TRNG data table with improved schema
-	_, err := d.db.Exec(`
-		CREATE TABLE IF NOT EXISTS trng_data (
-			id INTEGER PRIMARY KEY,
-			hash BLOB NOT NULL,
-			hash_hex VARCHAR(64) NOT NULL,
-			timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			consumed BOOLEAN DEFAULT FALSE,
-			source VARCHAR(20) DEFAULT 'hardware',
-			chunk_size INTEGER DEFAULT 32
-		)
-	`)
+// rekeyBatchSize bounds how many rows Rekey re-encrypts per transaction, so
+// rotating a large pool doesn't hold one transaction open for the entire
+// table.
+const rekeyBatchSize = 500
+
+// Rekey re-encrypts every stored trng_data.hash and fortuna_data.data value
+// under a new key derived from newProvider, streaming through both tables in
+// bounded batches. d.mutex is held for the entire rotation, not just one
+// batch at a time: releasing it between batches let a concurrent
+// StoreTRNGHash/StoreFortunaData land a row under the old key after that
+// table's sweep had already moved past it, leaving a straggler row that
+// GetTRNGHashes/GetFortunaData/ConsumeAtomic could never decrypt once
+// d.encryptor was swapped to the new key. Batching still bounds each
+// transaction to rekeyBatchSize rows (so a rotation over a large pool isn't
+// one unbounded transaction) and makes Rekey resumable if it fails partway
+// through, but StoreTRNGHash/GetTRNGHashes/etc. are blocked for the whole
+// rotation rather than being safely interleaved with it.
+func (d *DuckDBHandler) Rekey(newProvider KeyProvider) error {
+	newEnc, err := newEncryptor(newProvider)
 	if err != nil {
-		return fmt.Errorf("failed to create trng_data table: %w", err)
+		return fmt.Errorf("failed to initialize new encryption key: %w", err)
 	}
 
-	// Create Fortuna data table with improved schema
-	_, err = d.db.Exec(`
-		CREATE TABLE IF NOT EXISTS fortuna_data (
-			id INTEGER PRIMARY KEY,
-			data BLOB NOT NULL,
-			timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			consumed BOOLEAN DEFAULT FALSE,
-			chunk_size INTEGER DEFAULT 32,
-			amplification_factor INTEGER DEFAULT 4
-		)
-	`)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err := d.rekeyTRNGData(newEnc); err != nil {
+		return err
+	}
+	if err := d.rekeyFortunaData(newEnc); err != nil {
+		return err
+	}
+
+	d.encryptor = newEnc
+	return nil
+}
+
+// rekeyTRNGData re-encrypts trng_data.hash (and its hash_hex mirror) in
+// batches of rekeyBatchSize rows, one transaction per batch. Callers must
+// hold d.mutex for the duration of the whole sweep.
+func (d *DuckDBHandler) rekeyTRNGData(newEnc *Encryptor) error {
+	lastID := 0
+	for {
+		n, newLastID, err := d.rekeyTRNGBatch(newEnc, lastID)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		lastID = newLastID
+		if n < rekeyBatchSize {
+			return nil
+		}
+	}
+}
+
+// rekeyTRNGBatch re-encrypts up to rekeyBatchSize trng_data rows with id >
+// afterID. Callers must hold d.mutex.
+func (d *DuckDBHandler) rekeyTRNGBatch(newEnc *Encryptor, afterID int) (n int, lastID int, err error) {
+	rows, err := d.db.Query("SELECT id, hash FROM trng_data WHERE id > ? ORDER BY id LIMIT ?", afterID, rekeyBatchSize)
 	if err != nil {
-		return fmt.Errorf("failed to create fortuna_data table: %w", err)
+		return 0, 0, fmt.Errorf("failed to read trng_data for rekey: %w", err)
 	}
 
-	// Create metadata table for configuration
-	_, err = d.db.Exec(`
-		CREATE TABLE IF NOT EXISTS metadata (
-			key VARCHAR(50) PRIMARY KEY,
-			value VARCHAR(255) NOT NULL,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
+	type row struct {
+		id   int
+		hash []byte
+	}
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.hash); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan trng_data row for rekey: %w", err)
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+
+	if len(batch) == 0 {
+		return 0, 0, nil
+	}
+
+	tx, err := d.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to create metadata table: %w", err)
+		return 0, 0, fmt.Errorf("failed to begin rekey transaction: %w", err)
 	}
 
-	// Create indexes for better query performance
-	_, err = d.db.Exec(`CREATE INDEX IF NOT EXISTS idx_trng_timestamp ON trng_data(timestamp)`)
+	for _, r := range batch {
+		plaintext := r.hash
+		if d.encryptor != nil {
+			plaintext, err = d.encryptor.DecryptHash(r.hash)
+			if err != nil {
+				tx.Rollback()
+				return 0, 0, fmt.Errorf("failed to decrypt trng_data row %d for rekey: %w", r.id, err)
+			}
+		}
+
+		ciphertext, err := newEnc.EncryptHash(plaintext)
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("failed to re-encrypt trng_data row %d: %w", r.id, err)
+		}
+
+		if _, err := tx.Exec(
+			"UPDATE trng_data SET hash = ?, hash_hex = ? WHERE id = ?",
+			ciphertext, hex.EncodeToString(ciphertext), r.id,
+		); err != nil {
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("failed to update trng_data row %d for rekey: %w", r.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit trng_data rekey batch: %w", err)
+	}
+
+	return len(batch), batch[len(batch)-1].id, nil
+}
+
+// rekeyFortunaData re-encrypts fortuna_data.data in batches of
+// rekeyBatchSize rows, one transaction per batch. Callers must hold
+// d.mutex for the duration of the whole sweep.
+func (d *DuckDBHandler) rekeyFortunaData(newEnc *Encryptor) error {
+	lastID := 0
+	for {
+		n, newLastID, err := d.rekeyFortunaBatch(newEnc, lastID)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		lastID = newLastID
+		if n < rekeyBatchSize {
+			return nil
+		}
+	}
+}
+
+// rekeyFortunaBatch re-encrypts up to rekeyBatchSize fortuna_data rows with
+// id > afterID. Callers must hold d.mutex.
+func (d *DuckDBHandler) rekeyFortunaBatch(newEnc *Encryptor, afterID int) (n int, lastID int, err error) {
+	rows, err := d.db.Query("SELECT id, data FROM fortuna_data WHERE id > ? ORDER BY id LIMIT ?", afterID, rekeyBatchSize)
 	if err != nil {
-		return fmt.Errorf("failed to create index on trng_data: %w", err)
+		return 0, 0, fmt.Errorf("failed to read fortuna_data for rekey: %w", err)
 	}
 
-	_, err = d.db.Exec(`CREATE INDEX IF NOT EXISTS idx_fortuna_timestamp ON fortuna_data(timestamp)`)
+	type row struct {
+		id   int
+		data []byte
+	}
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.data); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan fortuna_data row for rekey: %w", err)
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+
+	if len(batch) == 0 {
+		return 0, 0, nil
+	}
+
+	tx, err := d.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to create index on fortuna_data: %w", err)
+		return 0, 0, fmt.Errorf("failed to begin rekey transaction: %w", err)
 	}
 
-	// Configure DuckDB for better performance
-	_, err = d.db.Exec(`PRAGMA memory_limit='256MB'`)
+	for _, r := range batch {
+		plaintext := r.data
+		if d.encryptor != nil {
+			plaintext, err = d.encryptor.DecryptData(r.data)
+			if err != nil {
+				tx.Rollback()
+				return 0, 0, fmt.Errorf("failed to decrypt fortuna_data row %d for rekey: %w", r.id, err)
+			}
+		}
+
+		ciphertext, err := newEnc.EncryptData(plaintext)
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("failed to re-encrypt fortuna_data row %d: %w", r.id, err)
+		}
+
+		if _, err := tx.Exec("UPDATE fortuna_data SET data = ? WHERE id = ?", ciphertext, r.id); err != nil {
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("failed to update fortuna_data row %d for rekey: %w", r.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit fortuna_data rekey batch: %w", err)
+	}
+
+	return len(batch), batch[len(batch)-1].id, nil
+}
+
+// prepareStatements prepares the hot-path insert statements once at
+// startup so StoreTRNGHash/StoreFortunaData don't re-parse and re-plan SQL
+// on every call.
+func (d *DuckDBHandler) prepareStatements() error {
+	trngStmt, err := d.db.Prepare("INSERT INTO trng_data (hash, hash_hex, source, chunk_size) VALUES (?, ?, ?, 32)")
 	if err != nil {
-		log.Printf("Warning: Failed to set memory limit: %v", err)
+		return fmt.Errorf("failed to prepare TRNG insert statement: %w", err)
+	}
+	d.trngInsertStmt = trngStmt
+
+	fortunaStmt, err := d.db.Prepare("INSERT INTO fortuna_data (data, chunk_size, amplification_factor) VALUES (?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare Fortuna insert statement: %w", err)
 	}
+	d.fortunaInsertStmt = fortunaStmt
+
+	return nil
+}
 
+// configurePragmas applies DuckDB session settings that aren't part of the
+// versioned schema.
+func (d *DuckDBHandler) configurePragmas() error {
+	_, err := d.db.Exec(`PRAGMA memory_limit='256MB'`)
+	if err != nil {
+		log.Printf("Warning: Failed to set memory limit: %v", err)
+	}
 	return nil
 }
 
@@ -110,8 +309,17 @@ func (d *DuckDBHandler) StoreTRNGHash(hash []byte, source string) error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	// Generate hex representation
-	hashHex := hex.EncodeToString(hash)
+	// Encrypt at rest if an encryptor is configured; otherwise store the
+	// hash as-is.
+	storedHash := hash
+	if d.encryptor != nil {
+		var err error
+		storedHash, err = d.encryptor.EncryptHash(hash)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt TRNG hash: %w", err)
+		}
+	}
+	hashHex := hex.EncodeToString(storedHash)
 
 	// Use batched insertions for better performance
 	tx, err := d.db.Begin()
@@ -124,8 +332,9 @@ func (d *DuckDBHandler) StoreTRNGHash(hash []byte, source string) error {
 		}
 	}()
 
-	// Insert new hash with source information
-	_, err = tx.Exec("INSERT INTO trng_data (hash, hash_hex, source, chunk_size) VALUES (?, ?, ?, 32)", hash, hashHex, source)
+	// Insert new hash with source information using the statement prepared
+	// once at startup.
+	_, err = tx.Stmt(d.trngInsertStmt).Exec(storedHash, hashHex, source)
 	if err != nil {
 		return fmt.Errorf("failed to insert TRNG hash: %w", err)
 	}
@@ -136,14 +345,19 @@ func (d *DuckDBHandler) StoreTRNGHash(hash []byte, source string) error {
 		WHERE id IN (
 			SELECT id FROM trng_data
 			ORDER BY timestamp ASC
-			LIMIT (SELECT MAX(0, COUNT(*) - ?) FROM trng_data)
+			LIMIT GREATEST(0, (SELECT COUNT(*) FROM trng_data) - ?)
 		)
 	`, d.trngQueueSize)
 	if err != nil {
 		return fmt.Errorf("failed to maintain TRNG queue size: %w", err)
 	}
 
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	d.events.publish(Event{Pool: "trng", Source: EventSource(source), Type: EventStored, BatchSize: 1, Timestamp: time.Now()})
+	return nil
 }
 
 // Legacy method for backward compatibility
@@ -151,11 +365,50 @@ func (d *DuckDBHandler) StoreTRNGHashLegacy(hash []byte) error {
 	return d.StoreTRNGHash(hash, "hardware")
 }
 
+// StoreTRNGHealthFailure records a sample that failed a continuous health
+// test. The row is inserted already consumed and tagged health_status
+// 'failed' so it is kept for post-mortem but never served as entropy.
+func (d *DuckDBHandler) StoreTRNGHealthFailure(sample []byte, source string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	storedSample := sample
+	if d.encryptor != nil {
+		var err error
+		storedSample, err = d.encryptor.EncryptHash(sample)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt TRNG health failure sample: %w", err)
+		}
+	}
+	hashHex := hex.EncodeToString(storedSample)
+
+	_, err := d.db.Exec(
+		"INSERT INTO trng_data (hash, hash_hex, source, chunk_size, consumed, health_status) VALUES (?, ?, ?, 32, TRUE, 'failed')",
+		storedSample, hashHex, source,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record TRNG health failure: %w", err)
+	}
+
+	return nil
+}
+
 // StoreFortunaData stores Fortuna-generated data and maintains queue size
 func (d *DuckDBHandler) StoreFortunaData(data []byte, chunkSize int, amplificationFactor int) error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	// Encrypt at rest if an encryptor is configured; otherwise store the
+	// data as-is.
+	storedData := data
+	if d.encryptor != nil {
+		var err error
+		storedData, err = d.encryptor.EncryptData(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt Fortuna data: %w", err)
+		}
+	}
+
 	tx, err := d.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -166,9 +419,9 @@ func (d *DuckDBHandler) StoreFortunaData(data []byte, chunkSize int, amplificati
 		}
 	}()
 
-	// Insert new data with additional metadata
-	_, err = tx.Exec("INSERT INTO fortuna_data (data, chunk_size, amplification_factor) VALUES (?, ?, ?)",
-		data, chunkSize, amplificationFactor)
+	// Insert new data with additional metadata using the statement prepared
+	// once at startup.
+	_, err = tx.Stmt(d.fortunaInsertStmt).Exec(storedData, chunkSize, amplificationFactor)
 	if err != nil {
 		return fmt.Errorf("failed to insert Fortuna data: %w", err)
 	}
@@ -179,14 +432,19 @@ func (d *DuckDBHandler) StoreFortunaData(data []byte, chunkSize int, amplificati
 		WHERE id IN (
 			SELECT id FROM fortuna_data
 			ORDER BY timestamp ASC
-			LIMIT (SELECT MAX(0, COUNT(*) - ?) FROM fortuna_data)
+			LIMIT GREATEST(0, (SELECT COUNT(*) FROM fortuna_data) - ?)
 		)
 	`, d.fortunaQueueSize)
 	if err != nil {
 		return fmt.Errorf("failed to maintain Fortuna queue size: %w", err)
 	}
 
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	d.events.publish(Event{Pool: "fortuna", Type: EventStored, BatchSize: 1, Timestamp: time.Now()})
+	return nil
 }
 
 // Legacy method for backward compatibility
@@ -228,25 +486,25 @@ func (d *DuckDBHandler) GetTRNGHashes(limit, offset int, consume bool) ([][]byte
 		ids = append(ids, id)
 	}
 
-	if consume && len(ids) > 0 {
-		// Mark hashes as consumed
-		tx, err := d.db.Begin()
-		if err != nil {
-			return nil, fmt.Errorf("failed to begin transaction: %w", err)
-		}
-
-		for _, id := range ids {
-			_, err = tx.Exec("UPDATE trng_data SET consumed = TRUE WHERE id = ?", id)
+	if d.encryptor != nil {
+		for i, hash := range hashes {
+			plaintext, err := d.encryptor.DecryptHash(hash)
 			if err != nil {
-				tx.Rollback()
-				return nil, fmt.Errorf("failed to mark TRNG hash as consumed: %w", err)
+				return nil, fmt.Errorf("failed to decrypt TRNG hash: %w", err)
 			}
+			hashes[i] = plaintext
 		}
+	}
 
-		err = tx.Commit()
-		if err != nil {
-			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	if consume && len(ids) > 0 {
+		// Mark every returned row consumed in a single statement instead
+		// of one UPDATE per id.
+		query := fmt.Sprintf("UPDATE trng_data SET consumed = TRUE WHERE id IN (%s)", qPlaceholders(len(ids)))
+		if _, err := d.db.Exec(query, intArgs(ids)...); err != nil {
+			return nil, fmt.Errorf("failed to mark TRNG hashes as consumed: %w", err)
 		}
+
+		d.events.publish(Event{Pool: "trng", Type: EventConsumed, BatchSize: len(ids), Timestamp: time.Now()})
 	}
 
 	return hashes, nil
@@ -286,28 +544,76 @@ func (d *DuckDBHandler) GetFortunaData(limit, offset int, consume bool) ([][]byt
 		ids = append(ids, id)
 	}
 
+	if d.encryptor != nil {
+		for i, data := range dataSlices {
+			plaintext, err := d.encryptor.DecryptData(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt Fortuna data: %w", err)
+			}
+			dataSlices[i] = plaintext
+		}
+	}
+
 	if consume && len(ids) > 0 {
-		// Mark data as consumed
-		tx, err := d.db.Begin()
-		if err != nil {
-			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		// Mark every returned row consumed in a single statement instead
+		// of one UPDATE per id.
+		query := fmt.Sprintf("UPDATE fortuna_data SET consumed = TRUE WHERE id IN (%s)", qPlaceholders(len(ids)))
+		if _, err := d.db.Exec(query, intArgs(ids)...); err != nil {
+			return nil, fmt.Errorf("failed to mark Fortuna data as consumed: %w", err)
 		}
 
-		for _, id := range ids {
-			_, err = tx.Exec("UPDATE fortuna_data SET consumed = TRUE WHERE id = ?", id)
+		d.events.publish(Event{Pool: "fortuna", Type: EventConsumed, BatchSize: len(ids), Timestamp: time.Now()})
+	}
+
+	return dataSlices, nil
+}
+
+// ConsumeAtomic removes up to limit unconsumed TRNG hashes and returns them
+// in a single DELETE ... RETURNING statement, so the rows are gone the
+// moment they're handed out rather than lingering as "consumed" history.
+func (d *DuckDBHandler) ConsumeAtomic(limit int) ([][]byte, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	rows, err := d.db.Query(`
+		DELETE FROM trng_data
+		WHERE id IN (
+			SELECT id FROM trng_data
+			WHERE consumed = FALSE
+			ORDER BY timestamp ASC
+			LIMIT ?
+		)
+		RETURNING hash
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume TRNG hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes [][]byte
+	for rows.Next() {
+		var hash []byte
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan consumed TRNG hash: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if d.encryptor != nil {
+		for i, hash := range hashes {
+			plaintext, err := d.encryptor.DecryptHash(hash)
 			if err != nil {
-				tx.Rollback()
-				return nil, fmt.Errorf("failed to mark Fortuna data as consumed: %w", err)
+				return nil, fmt.Errorf("failed to decrypt consumed TRNG hash: %w", err)
 			}
+			hashes[i] = plaintext
 		}
+	}
 
-		err = tx.Commit()
-		if err != nil {
-			return nil, fmt.Errorf("failed to commit transaction: %w", err)
-		}
+	if len(hashes) > 0 {
+		d.events.publish(Event{Pool: "trng", Type: EventConsumed, BatchSize: len(hashes), Timestamp: time.Now()})
 	}
 
-	return dataSlices, nil
+	return hashes, nil
 }
 
 // GetStats returns statistics about the database
@@ -365,6 +671,11 @@ func (d *DuckDBHandler) GetStats() (map[string]interface{}, error) {
 	stats["fortuna_queue_full"] = fortunaCount >= d.fortunaQueueSize
 	stats["database_size_bytes"] = d.getDatabaseSizeEstimate()
 
+	stats["encryption_enabled"] = d.encryptor != nil
+	if d.encryptor != nil {
+		stats["encryption_key_fingerprint"] = d.encryptor.Fingerprint()
+	}
+
 	return stats, nil
 }
 
@@ -440,6 +751,12 @@ func (d *DuckDBHandler) getDatabaseSizeEstimate() int64 {
 
 // Close closes the database connection
 func (d *DuckDBHandler) Close() error {
+	if d.trngInsertStmt != nil {
+		d.trngInsertStmt.Close()
+	}
+	if d.fortunaInsertStmt != nil {
+		d.fortunaInsertStmt.Close()
+	}
 	return d.db.Close()
 }
 
@@ -463,12 +780,12 @@ func (d *DuckDBHandler) UpdateQueueSizes(trngQueueSize, fortunaQueueSize int) er
 	}()
 
 	// Trim TRNG queue
-	_, err = tx.Exec(`
+	trngResult, err := tx.Exec(`
 		DELETE FROM trng_data
 		WHERE id IN (
 			SELECT id FROM trng_data
 			ORDER BY timestamp ASC
-			LIMIT (SELECT MAX(0, COUNT(*) - ?) FROM trng_data)
+			LIMIT GREATEST(0, (SELECT COUNT(*) FROM trng_data) - ?)
 		)
 	`, trngQueueSize)
 	if err != nil {
@@ -476,19 +793,30 @@ func (d *DuckDBHandler) UpdateQueueSizes(trngQueueSize, fortunaQueueSize int) er
 	}
 
 	// Trim Fortuna queue
-	_, err = tx.Exec(`
+	fortunaResult, err := tx.Exec(`
 		DELETE FROM fortuna_data
 		WHERE id IN (
 			SELECT id FROM fortuna_data
 			ORDER BY timestamp ASC
-			LIMIT (SELECT MAX(0, COUNT(*) - ?) FROM fortuna_data)
+			LIMIT GREATEST(0, (SELECT COUNT(*) FROM fortuna_data) - ?)
 		)
 	`, fortunaQueueSize)
 	if err != nil {
 		return fmt.Errorf("failed to trim Fortuna queue: %w", err)
 	}
 
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	if trimmed, _ := trngResult.RowsAffected(); trimmed > 0 {
+		d.events.publish(Event{Pool: "trng", Type: EventTrimmed, BatchSize: int(trimmed), Timestamp: time.Now()})
+	}
+	if trimmed, _ := fortunaResult.RowsAffected(); trimmed > 0 {
+		d.events.publish(Event{Pool: "fortuna", Type: EventTrimmed, BatchSize: int(trimmed), Timestamp: time.Now()})
+	}
+
+	return nil
 }
 
 // HealthCheck checks if the database is accessible
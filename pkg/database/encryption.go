@@ -0,0 +1,190 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// aeadKeySize is the key length required by AES-256-GCM.
+const aeadKeySize = 32
+
+// KeyProvider supplies the master key material used to derive the per-column
+// AEAD keys that encrypt the trng_data.hash and fortuna_data.data columns.
+// Implementations should return at least aeadKeySize bytes of entropy.
+type KeyProvider interface {
+	MasterKey() ([]byte, error)
+}
+
+// EnvKeyProvider reads the master key as a hex string from an environment
+// variable, e.g. LOKEY_MASTER_KEY.
+type EnvKeyProvider struct {
+	VarName string
+}
+
+// MasterKey implements KeyProvider.
+func (p EnvKeyProvider) MasterKey() ([]byte, error) {
+	raw := os.Getenv(p.VarName)
+	if raw == "" {
+		return nil, fmt.Errorf("environment variable %q is not set", p.VarName)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("environment variable %q is not valid hex: %w", p.VarName, err)
+	}
+	return key, nil
+}
+
+// FileKeyProvider reads the master key as a hex string from a file on disk,
+// e.g. a secret mounted by an orchestrator.
+type FileKeyProvider struct {
+	Path string
+}
+
+// MasterKey implements KeyProvider.
+func (p FileKeyProvider) MasterKey() ([]byte, error) {
+	raw, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %q: %w", p.Path, err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("key file %q does not contain valid hex: %w", p.Path, err)
+	}
+	return key, nil
+}
+
+// FuncKeyProvider adapts an arbitrary callback to KeyProvider, so a PKCS#11
+// or HSM client's key-fetch call can be used without this package depending
+// on a specific HSM library.
+type FuncKeyProvider func() ([]byte, error)
+
+// MasterKey implements KeyProvider.
+func (f FuncKeyProvider) MasterKey() ([]byte, error) {
+	return f()
+}
+
+// Encryptor holds the AEAD ciphers derived from a KeyProvider's master key,
+// one per encrypted column, plus a fingerprint operators can use to confirm
+// which key is in use without ever exposing the key itself.
+type Encryptor struct {
+	hashAEAD    cipher.AEAD
+	dataAEAD    cipher.AEAD
+	fingerprint string
+}
+
+// newEncryptor derives the hash and data column keys from provider's master
+// key via HKDF-SHA256, one derivation per column so a compromise of one
+// column's key doesn't expose the other's.
+func newEncryptor(provider KeyProvider) (*Encryptor, error) {
+	master, err := provider.MasterKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain master key: %w", err)
+	}
+	if len(master) < aeadKeySize {
+		return nil, fmt.Errorf("master key must be at least %d bytes, got %d", aeadKeySize, len(master))
+	}
+
+	hashKey, err := hkdfKey(master, "lokey-trng-hash-v1")
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := hkdfKey(master, "lokey-fortuna-data-v1")
+	if err != nil {
+		return nil, err
+	}
+
+	hashAEAD, err := newGCM(hashKey)
+	if err != nil {
+		return nil, err
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := sha256.Sum256(master)
+
+	return &Encryptor{
+		hashAEAD:    hashAEAD,
+		dataAEAD:    dataAEAD,
+		fingerprint: hex.EncodeToString(fingerprint[:8]),
+	}, nil
+}
+
+// hkdfKey derives a column-scoped AES-256 key from master via HKDF-SHA256,
+// using info as the derivation label.
+func hkdfKey(master []byte, info string) ([]byte, error) {
+	kdf := hkdf.New(sha256.New, master, nil, []byte(info))
+	key := make([]byte, aeadKeySize)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, fmt.Errorf("failed to derive key for %q: %w", info, err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize AES cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptHash seals plaintext for the trng_data.hash column.
+func (e *Encryptor) EncryptHash(plaintext []byte) ([]byte, error) {
+	return seal(e.hashAEAD, plaintext)
+}
+
+// DecryptHash opens a value previously sealed by EncryptHash.
+func (e *Encryptor) DecryptHash(ciphertext []byte) ([]byte, error) {
+	return open(e.hashAEAD, ciphertext)
+}
+
+// EncryptData seals plaintext for the fortuna_data.data column.
+func (e *Encryptor) EncryptData(plaintext []byte) ([]byte, error) {
+	return seal(e.dataAEAD, plaintext)
+}
+
+// DecryptData opens a value previously sealed by EncryptData.
+func (e *Encryptor) DecryptData(ciphertext []byte) ([]byte, error) {
+	return open(e.dataAEAD, ciphertext)
+}
+
+// Fingerprint returns a non-reversible identifier for the key currently in
+// use, so operators can verify a Rekey actually rotated the key.
+func (e *Encryptor) Fingerprint() string {
+	return e.fingerprint
+}
+
+// seal generates a fresh random nonce and prepends it to the ciphertext, so
+// each encrypted column value is self-contained.
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open splits the leading nonce back off ciphertext and authenticates/
+// decrypts the remainder.
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, errors.New("ciphertext shorter than nonce")
+	}
+	nonce, sealed := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	return aead.Open(nil, nonce, sealed, nil)
+}
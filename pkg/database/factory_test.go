@@ -0,0 +1,67 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNewStoreDispatchesByScheme confirms NewStore routes each supported
+// scheme to its matching driver rather than, say, always falling through to
+// DuckDB. duckdb/sqlite are exercised for real since they need no external
+// server; postgres is checked indirectly, since there's no live Postgres in
+// this environment: NewPostgresHandler itself reaches out over the network
+// in setupTables, so if the error comes back as a connection failure rather
+// than "unsupported store scheme" we know NewStore dispatched correctly.
+func TestNewStoreDispatchesByScheme(t *testing.T) {
+	dir := t.TempDir()
+
+	duckStore, err := NewStore("duckdb://"+filepath.Join(dir, "lokey.db"), 0, 0)
+	if err != nil {
+		t.Fatalf("NewStore(duckdb://...) failed: %v", err)
+	}
+	defer duckStore.Close()
+	if _, ok := duckStore.(*DuckDBHandler); !ok {
+		t.Fatalf("NewStore(duckdb://...) returned %T, want *DuckDBHandler", duckStore)
+	}
+
+	sqliteStore, err := NewStore("sqlite://"+filepath.Join(dir, "lokey.sqlite"), 0, 0)
+	if err != nil {
+		t.Fatalf("NewStore(sqlite://...) failed: %v", err)
+	}
+	defer sqliteStore.Close()
+	if _, ok := sqliteStore.(*SQLiteHandler); !ok {
+		t.Fatalf("NewStore(sqlite://...) returned %T, want *SQLiteHandler", sqliteStore)
+	}
+
+	sqlite3Store, err := NewStore("sqlite3://"+filepath.Join(dir, "lokey2.sqlite"), 0, 0)
+	if err != nil {
+		t.Fatalf("NewStore(sqlite3://...) failed: %v", err)
+	}
+	defer sqlite3Store.Close()
+	if _, ok := sqlite3Store.(*SQLiteHandler); !ok {
+		t.Fatalf("NewStore(sqlite3://...) returned %T, want *SQLiteHandler", sqlite3Store)
+	}
+
+	_, pgErr := NewStore("postgres://nonexistent-host-for-lokey-tests.invalid:5432/lokey", 0, 0)
+	if pgErr == nil {
+		t.Fatal("expected NewStore(postgres://...) against an unreachable host to fail")
+	}
+	if strings.Contains(pgErr.Error(), "unsupported store scheme") {
+		t.Fatalf("NewStore(postgres://...) did not dispatch to NewPostgresHandler: %v", pgErr)
+	}
+}
+
+func TestNewStoreRejectsUnknownScheme(t *testing.T) {
+	_, err := NewStore("mysql://localhost/lokey", 0, 0)
+	if err == nil {
+		t.Fatal("expected NewStore to reject an unsupported scheme")
+	}
+}
+
+func TestNewStoreRejectsMissingScheme(t *testing.T) {
+	_, err := NewStore("lokey.db", 0, 0)
+	if err == nil {
+		t.Fatal("expected NewStore to reject a connection URL with no scheme")
+	}
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migration is a single numbered schema change with its forward (up) and
+// reverse (down) SQL.
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// LatestMigrationVersion means "migrate all the way forward" when passed as
+// the targetVersion to Migrate.
+const LatestMigrationVersion = -1
+
+// loadMigrations reads migrations/*.sql out of the embedded filesystem and
+// groups each numbered pair of *.up.sql / *.down.sql files into a migration,
+// sorted by version ascending.
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*migration)
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		version, label, err := parseMigrationFilename(name, direction)
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: label}
+			byVersion[version] = m
+		}
+
+		if direction == "up" {
+			m.up = string(contents)
+		} else {
+			m.down = string(contents)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %04d is missing an .up.sql file", m.version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	return migrations, nil
+}
+
+// parseMigrationFilename extracts the version and name out of a filename
+// shaped like "0001_initial_schema.up.sql".
+func parseMigrationFilename(name, direction string) (int, string, error) {
+	base := strings.TrimSuffix(name, "."+direction+".sql")
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("migration filename %q is not of the form NNNN_name.%s.sql", name, direction)
+	}
+
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("migration filename %q has a non-numeric version: %w", name, err)
+	}
+
+	return version, parts[1], nil
+}
+
+// Migrate brings the database schema to targetVersion, applying up
+// migrations if the current version is behind it or down migrations if
+// it's ahead. Pass LatestMigrationVersion to migrate all the way forward.
+// It is invoked once from NewDuckDBHandler so on-disk files created by an
+// older binary are brought up to date before any query runs against them.
+func (d *DuckDBHandler) Migrate(ctx context.Context, targetVersion int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	if targetVersion == LatestMigrationVersion {
+		targetVersion = migrations[len(migrations)-1].version
+	}
+
+	if _, err := d.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	current, err := d.currentMigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case current < targetVersion:
+		for _, m := range migrations {
+			if m.version <= current || m.version > targetVersion {
+				continue
+			}
+			if err := d.applyMigration(ctx, m, true); err != nil {
+				return fmt.Errorf("failed to apply migration %04d_%s: %w", m.version, m.name, err)
+			}
+		}
+	case current > targetVersion:
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if m.version > current || m.version <= targetVersion {
+				continue
+			}
+			if m.down == "" {
+				return fmt.Errorf("migration %04d_%s has no down migration to roll back", m.version, m.name)
+			}
+			if err := d.applyMigration(ctx, m, false); err != nil {
+				return fmt.Errorf("failed to roll back migration %04d_%s: %w", m.version, m.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CurrentMigrationVersion returns the highest applied migration version, or
+// 0 if none have been applied yet, so callers (e.g. the CLI) can tell
+// whether a requested target would move the schema forward or backward.
+func (d *DuckDBHandler) CurrentMigrationVersion(ctx context.Context) (int, error) {
+	return d.currentMigrationVersion(ctx)
+}
+
+// currentMigrationVersion returns the highest applied migration version, or
+// 0 if none have been applied yet.
+func (d *DuckDBHandler) currentMigrationVersion(ctx context.Context) (int, error) {
+	var version int
+	err := d.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read current schema version: %w", err)
+	}
+	return version, nil
+}
+
+// applyMigration runs a single migration's up or down SQL and records (or
+// removes) its row in schema_migrations, all inside one transaction.
+func (d *DuckDBHandler) applyMigration(ctx context.Context, m migration, up bool) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	sqlScript := m.up
+	if !up {
+		sqlScript = m.down
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlScript); err != nil {
+		return err
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.version, m.name)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,543 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresHandler is a Store implementation backed by Postgres. It mirrors
+// DuckDBHandler's behaviour so the TRNG store, Fortuna store, and stats
+// endpoints work unchanged regardless of which driver is configured.
+type PostgresHandler struct {
+	db               *sql.DB
+	trngQueueSize    int
+	fortunaQueueSize int
+	mutex            sync.Mutex
+	events           eventHub
+
+	trngInsertStmt    *sql.Stmt
+	fortunaInsertStmt *sql.Stmt
+}
+
+// NewPostgresHandler creates a new Postgres-backed database handler.
+func NewPostgresHandler(dsn string, trngQueueSize, fortunaQueueSize int) (*PostgresHandler, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Postgres: %w", err)
+	}
+
+	handler := &PostgresHandler{
+		db:               db,
+		trngQueueSize:    trngQueueSize,
+		fortunaQueueSize: fortunaQueueSize,
+		mutex:            sync.Mutex{},
+	}
+
+	if err := handler.setupTables(); err != nil {
+		return nil, err
+	}
+
+	if err := handler.prepareStatements(); err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+// prepareStatements prepares the hot-path insert statements once at
+// startup so StoreTRNGHash/StoreFortunaData don't re-parse and re-plan SQL
+// on every call.
+func (p *PostgresHandler) prepareStatements() error {
+	trngStmt, err := p.db.Prepare("INSERT INTO trng_data (hash, hash_hex, source, chunk_size) VALUES ($1, $2, $3, 32)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare TRNG insert statement: %w", err)
+	}
+	p.trngInsertStmt = trngStmt
+
+	fortunaStmt, err := p.db.Prepare("INSERT INTO fortuna_data (data, chunk_size, amplification_factor) VALUES ($1, $2, $3)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare Fortuna insert statement: %w", err)
+	}
+	p.fortunaInsertStmt = fortunaStmt
+
+	return nil
+}
+
+// setupTables creates necessary tables if they don't exist
+func (p *PostgresHandler) setupTables() error {
+	_, err := p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS trng_data (
+			id BIGSERIAL PRIMARY KEY,
+			hash BYTEA NOT NULL,
+			hash_hex VARCHAR(64) NOT NULL,
+			timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			consumed BOOLEAN DEFAULT FALSE,
+			source VARCHAR(20) DEFAULT 'hardware',
+			chunk_size INTEGER DEFAULT 32,
+			health_status VARCHAR(20) DEFAULT 'ok'
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create trng_data table: %w", err)
+	}
+
+	// CREATE TABLE IF NOT EXISTS is a no-op against a database that already
+	// has trng_data from before health_status existed, so add the column
+	// explicitly for upgrades of an existing install.
+	_, err = p.db.Exec(`ALTER TABLE trng_data ADD COLUMN IF NOT EXISTS health_status VARCHAR(20) DEFAULT 'ok'`)
+	if err != nil {
+		return fmt.Errorf("failed to add health_status column to trng_data: %w", err)
+	}
+
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS fortuna_data (
+			id BIGSERIAL PRIMARY KEY,
+			data BYTEA NOT NULL,
+			timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			consumed BOOLEAN DEFAULT FALSE,
+			chunk_size INTEGER DEFAULT 32,
+			amplification_factor INTEGER DEFAULT 4
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create fortuna_data table: %w", err)
+	}
+
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS metadata (
+			key VARCHAR(50) PRIMARY KEY,
+			value VARCHAR(255) NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata table: %w", err)
+	}
+
+	_, err = p.db.Exec(`CREATE INDEX IF NOT EXISTS idx_trng_timestamp ON trng_data(timestamp)`)
+	if err != nil {
+		return fmt.Errorf("failed to create index on trng_data: %w", err)
+	}
+
+	_, err = p.db.Exec(`CREATE INDEX IF NOT EXISTS idx_fortuna_timestamp ON fortuna_data(timestamp)`)
+	if err != nil {
+		return fmt.Errorf("failed to create index on fortuna_data: %w", err)
+	}
+
+	return nil
+}
+
+// StoreTRNGHash stores a new TRNG hash and maintains queue size
+func (p *PostgresHandler) StoreTRNGHash(hash []byte, source string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	hashHex := hex.EncodeToString(hash)
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Stmt(p.trngInsertStmt).Exec(hash, hashHex, source)
+	if err != nil {
+		return fmt.Errorf("failed to insert TRNG hash: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM trng_data
+		WHERE id IN (
+			SELECT id FROM trng_data
+			ORDER BY timestamp ASC
+			LIMIT GREATEST(0, (SELECT COUNT(*) FROM trng_data) - $1)
+		)
+	`, p.trngQueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to maintain TRNG queue size: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	p.events.publish(Event{Pool: "trng", Source: EventSource(source), Type: EventStored, BatchSize: 1, Timestamp: time.Now()})
+	return nil
+}
+
+// StoreTRNGHealthFailure records a sample that failed a continuous health
+// test. The row is inserted already consumed and tagged health_status
+// 'failed' so it is kept for post-mortem but never served as entropy.
+func (p *PostgresHandler) StoreTRNGHealthFailure(sample []byte, source string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	hashHex := hex.EncodeToString(sample)
+
+	_, err := p.db.Exec(
+		"INSERT INTO trng_data (hash, hash_hex, source, chunk_size, consumed, health_status) VALUES ($1, $2, $3, 32, TRUE, 'failed')",
+		sample, hashHex, source,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record TRNG health failure: %w", err)
+	}
+
+	return nil
+}
+
+// StoreFortunaData stores Fortuna-generated data and maintains queue size
+func (p *PostgresHandler) StoreFortunaData(data []byte, chunkSize int, amplificationFactor int) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Stmt(p.fortunaInsertStmt).Exec(data, chunkSize, amplificationFactor)
+	if err != nil {
+		return fmt.Errorf("failed to insert Fortuna data: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM fortuna_data
+		WHERE id IN (
+			SELECT id FROM fortuna_data
+			ORDER BY timestamp ASC
+			LIMIT GREATEST(0, (SELECT COUNT(*) FROM fortuna_data) - $1)
+		)
+	`, p.fortunaQueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to maintain Fortuna queue size: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	p.events.publish(Event{Pool: "fortuna", Type: EventStored, BatchSize: 1, Timestamp: time.Now()})
+	return nil
+}
+
+// GetTRNGHashes retrieves TRNG hashes with pagination and optional consumption
+func (p *PostgresHandler) GetTRNGHashes(limit, offset int, consume bool) ([][]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	rows, err := p.db.Query(`
+		SELECT id, hash
+		FROM trng_data
+		WHERE consumed = FALSE
+		ORDER BY timestamp ASC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query TRNG hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes [][]byte
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		var hash []byte
+		if err := rows.Scan(&id, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan TRNG hash: %w", err)
+		}
+		hashes = append(hashes, hash)
+		ids = append(ids, id)
+	}
+
+	if consume && len(ids) > 0 {
+		if _, err := p.db.Exec("UPDATE trng_data SET consumed = TRUE WHERE id = ANY($1)", ids); err != nil {
+			return nil, fmt.Errorf("failed to mark TRNG hashes as consumed: %w", err)
+		}
+		p.events.publish(Event{Pool: "trng", Type: EventConsumed, BatchSize: len(ids), Timestamp: time.Now()})
+	}
+
+	return hashes, nil
+}
+
+// GetFortunaData retrieves Fortuna-generated data with pagination and optional consumption
+func (p *PostgresHandler) GetFortunaData(limit, offset int, consume bool) ([][]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	rows, err := p.db.Query(`
+		SELECT id, data
+		FROM fortuna_data
+		WHERE consumed = FALSE
+		ORDER BY timestamp ASC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Fortuna data: %w", err)
+	}
+	defer rows.Close()
+
+	var dataSlices [][]byte
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan Fortuna data: %w", err)
+		}
+		dataSlices = append(dataSlices, data)
+		ids = append(ids, id)
+	}
+
+	if consume && len(ids) > 0 {
+		if _, err := p.db.Exec("UPDATE fortuna_data SET consumed = TRUE WHERE id = ANY($1)", ids); err != nil {
+			return nil, fmt.Errorf("failed to mark Fortuna data as consumed: %w", err)
+		}
+		p.events.publish(Event{Pool: "fortuna", Type: EventConsumed, BatchSize: len(ids), Timestamp: time.Now()})
+	}
+
+	return dataSlices, nil
+}
+
+// ConsumeAtomic removes up to limit unconsumed TRNG hashes and returns them
+// in a single DELETE ... RETURNING statement, so the rows are gone the
+// moment they're handed out rather than lingering as "consumed" history.
+func (p *PostgresHandler) ConsumeAtomic(limit int) ([][]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	rows, err := p.db.Query(`
+		DELETE FROM trng_data
+		WHERE id IN (
+			SELECT id FROM trng_data
+			WHERE consumed = FALSE
+			ORDER BY timestamp ASC
+			LIMIT $1
+		)
+		RETURNING hash
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume TRNG hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes [][]byte
+	for rows.Next() {
+		var hash []byte
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan consumed TRNG hash: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if len(hashes) > 0 {
+		p.events.publish(Event{Pool: "trng", Type: EventConsumed, BatchSize: len(hashes), Timestamp: time.Now()})
+	}
+
+	return hashes, nil
+}
+
+// GetStats returns statistics about the database
+func (p *PostgresHandler) GetStats() (map[string]interface{}, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := make(map[string]interface{})
+
+	var trngCount, trngUnconsumedCount int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM trng_data").Scan(&trngCount); err != nil {
+		return nil, fmt.Errorf("failed to get TRNG count: %w", err)
+	}
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM trng_data WHERE consumed = FALSE").Scan(&trngUnconsumedCount); err != nil {
+		return nil, fmt.Errorf("failed to get unconsumed TRNG count: %w", err)
+	}
+
+	var hardwareCount, softwareCount int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM trng_data WHERE source = 'hardware'").Scan(&hardwareCount); err != nil {
+		return nil, fmt.Errorf("failed to get hardware TRNG count: %w", err)
+	}
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM trng_data WHERE source = 'software'").Scan(&softwareCount); err != nil {
+		return nil, fmt.Errorf("failed to get software TRNG count: %w", err)
+	}
+
+	var fortunaCount, fortunaUnconsumedCount int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM fortuna_data").Scan(&fortunaCount); err != nil {
+		return nil, fmt.Errorf("failed to get Fortuna count: %w", err)
+	}
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM fortuna_data WHERE consumed = FALSE").Scan(&fortunaUnconsumedCount); err != nil {
+		return nil, fmt.Errorf("failed to get unconsumed Fortuna count: %w", err)
+	}
+
+	stats["trng_total"] = trngCount
+	stats["trng_unconsumed"] = trngUnconsumedCount
+	stats["trng_queue_full"] = trngCount >= p.trngQueueSize
+	stats["trng_hardware_count"] = hardwareCount
+	stats["trng_software_count"] = softwareCount
+	stats["trng_hardware_percent"] = float64(hardwareCount) / float64(math.Max(float64(trngCount), 1.0)) * 100.0
+	stats["fortuna_total"] = fortunaCount
+	stats["fortuna_unconsumed"] = fortunaUnconsumedCount
+	stats["fortuna_queue_full"] = fortunaCount >= p.fortunaQueueSize
+
+	var dbSizeBytes int64
+	if err := p.db.QueryRow("SELECT pg_database_size(current_database())").Scan(&dbSizeBytes); err != nil {
+		log.Printf("Warning: failed to get Postgres database size: %v", err)
+	}
+	stats["database_size_bytes"] = dbSizeBytes
+
+	return stats, nil
+}
+
+// GetSourceStats returns detailed statistics about hardware vs software generated data
+func (p *PostgresHandler) GetSourceStats() (map[string]interface{}, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := make(map[string]interface{})
+
+	rows, err := p.db.Query(`
+		SELECT
+			to_char(timestamp, 'YYYY-MM-DD') as day,
+			source,
+			COUNT(*) as count
+		FROM trng_data
+		GROUP BY day, source
+		ORDER BY day DESC
+		LIMIT 30
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source stats: %w", err)
+	}
+	defer rows.Close()
+
+	dailyStats := make(map[string]map[string]int)
+	for rows.Next() {
+		var day, source string
+		var count int
+		if err := rows.Scan(&day, &source, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan source stats: %w", err)
+		}
+		if _, ok := dailyStats[day]; !ok {
+			dailyStats[day] = make(map[string]int)
+		}
+		dailyStats[day][source] = count
+	}
+
+	sourcePercentages := make(map[string]map[string]float64)
+	for day, counts := range dailyStats {
+		sourcePercentages[day] = make(map[string]float64)
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		for source, count := range counts {
+			sourcePercentages[day][source] = float64(count) / float64(total) * 100.0
+		}
+	}
+
+	stats["daily_counts"] = dailyStats
+	stats["daily_percentages"] = sourcePercentages
+
+	return stats, nil
+}
+
+// Close closes the database connection
+func (p *PostgresHandler) Close() error {
+	if p.trngInsertStmt != nil {
+		p.trngInsertStmt.Close()
+	}
+	if p.fortunaInsertStmt != nil {
+		p.fortunaInsertStmt.Close()
+	}
+	return p.db.Close()
+}
+
+// UpdateQueueSizes updates the queue sizes for TRNG and Fortuna data
+func (p *PostgresHandler) UpdateQueueSizes(trngQueueSize, fortunaQueueSize int) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.trngQueueSize = trngQueueSize
+	p.fortunaQueueSize = fortunaQueueSize
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	trngResult, err := tx.Exec(`
+		DELETE FROM trng_data
+		WHERE id IN (
+			SELECT id FROM trng_data
+			ORDER BY timestamp ASC
+			LIMIT GREATEST(0, (SELECT COUNT(*) FROM trng_data) - $1)
+		)
+	`, trngQueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to trim TRNG queue: %w", err)
+	}
+
+	fortunaResult, err := tx.Exec(`
+		DELETE FROM fortuna_data
+		WHERE id IN (
+			SELECT id FROM fortuna_data
+			ORDER BY timestamp ASC
+			LIMIT GREATEST(0, (SELECT COUNT(*) FROM fortuna_data) - $1)
+		)
+	`, fortunaQueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to trim Fortuna queue: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	if trimmed, _ := trngResult.RowsAffected(); trimmed > 0 {
+		p.events.publish(Event{Pool: "trng", Type: EventTrimmed, BatchSize: int(trimmed), Timestamp: time.Now()})
+	}
+	if trimmed, _ := fortunaResult.RowsAffected(); trimmed > 0 {
+		p.events.publish(Event{Pool: "fortuna", Type: EventTrimmed, BatchSize: int(trimmed), Timestamp: time.Now()})
+	}
+
+	return nil
+}
+
+// Subscribe registers a listener for TRNG and Fortuna pool events. Callers
+// should invoke the returned CancelFunc once they stop reading, e.g. when
+// an HTTP/WebSocket client disconnects.
+func (p *PostgresHandler) Subscribe(filter SubscriptionFilter) (<-chan Event, CancelFunc) {
+	return p.events.Subscribe(filter)
+}
+
+// HealthCheck checks if the database is accessible
+func (p *PostgresHandler) HealthCheck() bool {
+	if err := p.db.Ping(); err != nil {
+		log.Printf("Database health check failed: %v", err)
+		return false
+	}
+	return true
+}
+
+var _ Store = (*PostgresHandler)(nil)
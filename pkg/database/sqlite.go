@@ -0,0 +1,594 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteHandler is a Store implementation backed by SQLite. It is intended
+// for single-node deployments that want an embedded database without
+// DuckDB's analytical engine.
+type SQLiteHandler struct {
+	db               *sql.DB
+	dbPath           string
+	trngQueueSize    int
+	fortunaQueueSize int
+	mutex            sync.Mutex
+	events           eventHub
+
+	trngInsertStmt    *sql.Stmt
+	fortunaInsertStmt *sql.Stmt
+}
+
+// NewSQLiteHandler creates a new SQLite-backed database handler.
+func NewSQLiteHandler(dbPath string, trngQueueSize, fortunaQueueSize int) (*SQLiteHandler, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite: %w", err)
+	}
+
+	handler := &SQLiteHandler{
+		db:               db,
+		dbPath:           dbPath,
+		trngQueueSize:    trngQueueSize,
+		fortunaQueueSize: fortunaQueueSize,
+		mutex:            sync.Mutex{},
+	}
+
+	if err := handler.setupTables(); err != nil {
+		return nil, err
+	}
+
+	if err := handler.prepareStatements(); err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+// prepareStatements prepares the hot-path insert statements once at
+// startup so StoreTRNGHash/StoreFortunaData don't re-parse and re-plan SQL
+// on every call.
+func (s *SQLiteHandler) prepareStatements() error {
+	trngStmt, err := s.db.Prepare("INSERT INTO trng_data (hash, hash_hex, source, chunk_size) VALUES (?, ?, ?, 32)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare TRNG insert statement: %w", err)
+	}
+	s.trngInsertStmt = trngStmt
+
+	fortunaStmt, err := s.db.Prepare("INSERT INTO fortuna_data (data, chunk_size, amplification_factor) VALUES (?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare Fortuna insert statement: %w", err)
+	}
+	s.fortunaInsertStmt = fortunaStmt
+
+	return nil
+}
+
+// setupTables creates necessary tables if they don't exist
+func (s *SQLiteHandler) setupTables() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS trng_data (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hash BLOB NOT NULL,
+			hash_hex VARCHAR(64) NOT NULL,
+			timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			consumed BOOLEAN DEFAULT FALSE,
+			source VARCHAR(20) DEFAULT 'hardware',
+			chunk_size INTEGER DEFAULT 32,
+			health_status VARCHAR(20) DEFAULT 'ok'
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create trng_data table: %w", err)
+	}
+
+	// CREATE TABLE IF NOT EXISTS is a no-op against a database that already
+	// has trng_data from before health_status existed, so add the column
+	// explicitly for upgrades of an existing install. SQLite has no "ADD
+	// COLUMN IF NOT EXISTS", so check pragma table_info first.
+	hasHealthStatus, err := s.hasColumn("trng_data", "health_status")
+	if err != nil {
+		return err
+	}
+	if !hasHealthStatus {
+		if _, err := s.db.Exec(`ALTER TABLE trng_data ADD COLUMN health_status VARCHAR(20) DEFAULT 'ok'`); err != nil {
+			return fmt.Errorf("failed to add health_status column to trng_data: %w", err)
+		}
+	}
+
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS fortuna_data (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			data BLOB NOT NULL,
+			timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			consumed BOOLEAN DEFAULT FALSE,
+			chunk_size INTEGER DEFAULT 32,
+			amplification_factor INTEGER DEFAULT 4
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create fortuna_data table: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS metadata (
+			key VARCHAR(50) PRIMARY KEY,
+			value VARCHAR(255) NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata table: %w", err)
+	}
+
+	_, err = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_trng_timestamp ON trng_data(timestamp)`)
+	if err != nil {
+		return fmt.Errorf("failed to create index on trng_data: %w", err)
+	}
+
+	_, err = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_fortuna_timestamp ON fortuna_data(timestamp)`)
+	if err != nil {
+		return fmt.Errorf("failed to create index on fortuna_data: %w", err)
+	}
+
+	return nil
+}
+
+// hasColumn reports whether table already has the given column, so
+// setupTables can decide whether an ALTER TABLE is needed on an existing
+// database.
+func (s *SQLiteHandler) hasColumn(table, column string) (bool, error) {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s schema: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk); err != nil {
+			return false, fmt.Errorf("failed to scan %s schema: %w", table, err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// StoreTRNGHash stores a new TRNG hash and maintains queue size
+func (s *SQLiteHandler) StoreTRNGHash(hash []byte, source string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hashHex := hex.EncodeToString(hash)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Stmt(s.trngInsertStmt).Exec(hash, hashHex, source)
+	if err != nil {
+		return fmt.Errorf("failed to insert TRNG hash: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM trng_data
+		WHERE id IN (
+			SELECT id FROM trng_data
+			ORDER BY timestamp ASC
+			LIMIT MAX(0, (SELECT COUNT(*) FROM trng_data) - ?)
+		)
+	`, s.trngQueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to maintain TRNG queue size: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	s.events.publish(Event{Pool: "trng", Source: EventSource(source), Type: EventStored, BatchSize: 1, Timestamp: time.Now()})
+	return nil
+}
+
+// StoreTRNGHealthFailure records a sample that failed a continuous health
+// test. The row is inserted already consumed and tagged health_status
+// 'failed' so it is kept for post-mortem but never served as entropy.
+func (s *SQLiteHandler) StoreTRNGHealthFailure(sample []byte, source string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hashHex := hex.EncodeToString(sample)
+
+	_, err := s.db.Exec(
+		"INSERT INTO trng_data (hash, hash_hex, source, chunk_size, consumed, health_status) VALUES (?, ?, ?, 32, TRUE, 'failed')",
+		sample, hashHex, source,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record TRNG health failure: %w", err)
+	}
+
+	return nil
+}
+
+// StoreFortunaData stores Fortuna-generated data and maintains queue size
+func (s *SQLiteHandler) StoreFortunaData(data []byte, chunkSize int, amplificationFactor int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Stmt(s.fortunaInsertStmt).Exec(data, chunkSize, amplificationFactor)
+	if err != nil {
+		return fmt.Errorf("failed to insert Fortuna data: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM fortuna_data
+		WHERE id IN (
+			SELECT id FROM fortuna_data
+			ORDER BY timestamp ASC
+			LIMIT MAX(0, (SELECT COUNT(*) FROM fortuna_data) - ?)
+		)
+	`, s.fortunaQueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to maintain Fortuna queue size: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	s.events.publish(Event{Pool: "fortuna", Type: EventStored, BatchSize: 1, Timestamp: time.Now()})
+	return nil
+}
+
+// GetTRNGHashes retrieves TRNG hashes with pagination and optional consumption
+func (s *SQLiteHandler) GetTRNGHashes(limit, offset int, consume bool) ([][]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rows, err := s.db.Query(`
+		SELECT id, hash
+		FROM trng_data
+		WHERE consumed = FALSE
+		ORDER BY timestamp ASC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query TRNG hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes [][]byte
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		var hash []byte
+		if err := rows.Scan(&id, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan TRNG hash: %w", err)
+		}
+		hashes = append(hashes, hash)
+		ids = append(ids, id)
+	}
+
+	if consume && len(ids) > 0 {
+		if err := s.markConsumed("trng_data", ids); err != nil {
+			return nil, fmt.Errorf("failed to mark TRNG hashes as consumed: %w", err)
+		}
+		s.events.publish(Event{Pool: "trng", Type: EventConsumed, BatchSize: len(ids), Timestamp: time.Now()})
+	}
+
+	return hashes, nil
+}
+
+// GetFortunaData retrieves Fortuna-generated data with pagination and optional consumption
+func (s *SQLiteHandler) GetFortunaData(limit, offset int, consume bool) ([][]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rows, err := s.db.Query(`
+		SELECT id, data
+		FROM fortuna_data
+		WHERE consumed = FALSE
+		ORDER BY timestamp ASC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Fortuna data: %w", err)
+	}
+	defer rows.Close()
+
+	var dataSlices [][]byte
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan Fortuna data: %w", err)
+		}
+		dataSlices = append(dataSlices, data)
+		ids = append(ids, id)
+	}
+
+	if consume && len(ids) > 0 {
+		if err := s.markConsumed("fortuna_data", ids); err != nil {
+			return nil, fmt.Errorf("failed to mark Fortuna data as consumed: %w", err)
+		}
+		s.events.publish(Event{Pool: "fortuna", Type: EventConsumed, BatchSize: len(ids), Timestamp: time.Now()})
+	}
+
+	return dataSlices, nil
+}
+
+// markConsumed flags the given row ids as consumed in a single statement.
+// SQLite's driver has no array bind, so the "IN (?, ?, ...)" placeholders
+// are built inline instead of looping one UPDATE per id.
+func (s *SQLiteHandler) markConsumed(table string, ids []int) error {
+	query := fmt.Sprintf("UPDATE %s SET consumed = TRUE WHERE id IN (%s)", table, qPlaceholders(len(ids)))
+	_, err := s.db.Exec(query, intArgs(ids)...)
+	return err
+}
+
+// ConsumeAtomic removes up to limit unconsumed TRNG hashes and returns them
+// in a single DELETE ... RETURNING statement, so the rows are gone the
+// moment they're handed out rather than lingering as "consumed" history.
+func (s *SQLiteHandler) ConsumeAtomic(limit int) ([][]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rows, err := s.db.Query(`
+		DELETE FROM trng_data
+		WHERE id IN (
+			SELECT id FROM trng_data
+			WHERE consumed = FALSE
+			ORDER BY timestamp ASC
+			LIMIT ?
+		)
+		RETURNING hash
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume TRNG hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes [][]byte
+	for rows.Next() {
+		var hash []byte
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan consumed TRNG hash: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if len(hashes) > 0 {
+		s.events.publish(Event{Pool: "trng", Type: EventConsumed, BatchSize: len(hashes), Timestamp: time.Now()})
+	}
+
+	return hashes, nil
+}
+
+// GetStats returns statistics about the database
+func (s *SQLiteHandler) GetStats() (map[string]interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := make(map[string]interface{})
+
+	var trngCount, trngUnconsumedCount int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM trng_data").Scan(&trngCount); err != nil {
+		return nil, fmt.Errorf("failed to get TRNG count: %w", err)
+	}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM trng_data WHERE consumed = FALSE").Scan(&trngUnconsumedCount); err != nil {
+		return nil, fmt.Errorf("failed to get unconsumed TRNG count: %w", err)
+	}
+
+	var hardwareCount, softwareCount int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM trng_data WHERE source = 'hardware'").Scan(&hardwareCount); err != nil {
+		return nil, fmt.Errorf("failed to get hardware TRNG count: %w", err)
+	}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM trng_data WHERE source = 'software'").Scan(&softwareCount); err != nil {
+		return nil, fmt.Errorf("failed to get software TRNG count: %w", err)
+	}
+
+	var fortunaCount, fortunaUnconsumedCount int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM fortuna_data").Scan(&fortunaCount); err != nil {
+		return nil, fmt.Errorf("failed to get Fortuna count: %w", err)
+	}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM fortuna_data WHERE consumed = FALSE").Scan(&fortunaUnconsumedCount); err != nil {
+		return nil, fmt.Errorf("failed to get unconsumed Fortuna count: %w", err)
+	}
+
+	stats["trng_total"] = trngCount
+	stats["trng_unconsumed"] = trngUnconsumedCount
+	stats["trng_queue_full"] = trngCount >= s.trngQueueSize
+	stats["trng_hardware_count"] = hardwareCount
+	stats["trng_software_count"] = softwareCount
+	stats["trng_hardware_percent"] = float64(hardwareCount) / float64(math.Max(float64(trngCount), 1.0)) * 100.0
+	stats["fortuna_total"] = fortunaCount
+	stats["fortuna_unconsumed"] = fortunaUnconsumedCount
+	stats["fortuna_queue_full"] = fortunaCount >= s.fortunaQueueSize
+	stats["database_size_bytes"] = s.getDatabaseSize()
+
+	return stats, nil
+}
+
+// getDatabaseSize returns the on-disk size of the SQLite file.
+func (s *SQLiteHandler) getDatabaseSize() int64 {
+	info, err := os.Stat(s.dbPath)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// GetSourceStats returns detailed statistics about hardware vs software generated data
+func (s *SQLiteHandler) GetSourceStats() (map[string]interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := make(map[string]interface{})
+
+	rows, err := s.db.Query(`
+		SELECT
+			strftime('%Y-%m-%d', timestamp) as day,
+			source,
+			COUNT(*) as count
+		FROM trng_data
+		GROUP BY day, source
+		ORDER BY day DESC
+		LIMIT 30
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source stats: %w", err)
+	}
+	defer rows.Close()
+
+	dailyStats := make(map[string]map[string]int)
+	for rows.Next() {
+		var day, source string
+		var count int
+		if err := rows.Scan(&day, &source, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan source stats: %w", err)
+		}
+		if _, ok := dailyStats[day]; !ok {
+			dailyStats[day] = make(map[string]int)
+		}
+		dailyStats[day][source] = count
+	}
+
+	sourcePercentages := make(map[string]map[string]float64)
+	for day, counts := range dailyStats {
+		sourcePercentages[day] = make(map[string]float64)
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		for source, count := range counts {
+			sourcePercentages[day][source] = float64(count) / float64(total) * 100.0
+		}
+	}
+
+	stats["daily_counts"] = dailyStats
+	stats["daily_percentages"] = sourcePercentages
+
+	return stats, nil
+}
+
+// Close closes the database connection
+func (s *SQLiteHandler) Close() error {
+	if s.trngInsertStmt != nil {
+		s.trngInsertStmt.Close()
+	}
+	if s.fortunaInsertStmt != nil {
+		s.fortunaInsertStmt.Close()
+	}
+	return s.db.Close()
+}
+
+// UpdateQueueSizes updates the queue sizes for TRNG and Fortuna data
+func (s *SQLiteHandler) UpdateQueueSizes(trngQueueSize, fortunaQueueSize int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.trngQueueSize = trngQueueSize
+	s.fortunaQueueSize = fortunaQueueSize
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	trngResult, err := tx.Exec(`
+		DELETE FROM trng_data
+		WHERE id IN (
+			SELECT id FROM trng_data
+			ORDER BY timestamp ASC
+			LIMIT MAX(0, (SELECT COUNT(*) FROM trng_data) - ?)
+		)
+	`, trngQueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to trim TRNG queue: %w", err)
+	}
+
+	fortunaResult, err := tx.Exec(`
+		DELETE FROM fortuna_data
+		WHERE id IN (
+			SELECT id FROM fortuna_data
+			ORDER BY timestamp ASC
+			LIMIT MAX(0, (SELECT COUNT(*) FROM fortuna_data) - ?)
+		)
+	`, fortunaQueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to trim Fortuna queue: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	if trimmed, _ := trngResult.RowsAffected(); trimmed > 0 {
+		s.events.publish(Event{Pool: "trng", Type: EventTrimmed, BatchSize: int(trimmed), Timestamp: time.Now()})
+	}
+	if trimmed, _ := fortunaResult.RowsAffected(); trimmed > 0 {
+		s.events.publish(Event{Pool: "fortuna", Type: EventTrimmed, BatchSize: int(trimmed), Timestamp: time.Now()})
+	}
+
+	return nil
+}
+
+// Subscribe registers a listener for TRNG and Fortuna pool events. Callers
+// should invoke the returned CancelFunc once they stop reading, e.g. when
+// an HTTP/WebSocket client disconnects.
+func (s *SQLiteHandler) Subscribe(filter SubscriptionFilter) (<-chan Event, CancelFunc) {
+	return s.events.Subscribe(filter)
+}
+
+// HealthCheck checks if the database is accessible
+func (s *SQLiteHandler) HealthCheck() bool {
+	if err := s.db.Ping(); err != nil {
+		log.Printf("Database health check failed: %v", err)
+		return false
+	}
+	return true
+}
+
+var _ Store = (*SQLiteHandler)(nil)
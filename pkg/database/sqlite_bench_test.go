@@ -0,0 +1,76 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+)
+
+// setupBenchRows inserts n TRNG rows into an in-memory SQLite handler and
+// returns their ids, for benchmarking consumption paths without touching
+// disk.
+func setupBenchRows(b *testing.B, n int) (*SQLiteHandler, []int) {
+	b.Helper()
+
+	h, err := NewSQLiteHandler(":memory:", n, n)
+	if err != nil {
+		b.Fatalf("NewSQLiteHandler failed: %v", err)
+	}
+	b.Cleanup(func() { h.Close() })
+
+	for i := 0; i < n; i++ {
+		if err := h.StoreTRNGHash([]byte(fmt.Sprintf("%032d", i)), "software"); err != nil {
+			b.Fatalf("StoreTRNGHash failed: %v", err)
+		}
+	}
+
+	rows, err := h.db.Query("SELECT id FROM trng_data")
+	if err != nil {
+		b.Fatalf("failed to read ids: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			b.Fatalf("failed to scan id: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return h, ids
+}
+
+// markConsumedLoop is the pre-#chunk0-5 behaviour markConsumed replaced: one
+// UPDATE per row id instead of a single batched statement. Kept here only
+// so BenchmarkMarkConsumedLoop can show the throughput difference batching
+// bought.
+func markConsumedLoop(h *SQLiteHandler, ids []int) error {
+	for _, id := range ids {
+		if _, err := h.db.Exec("UPDATE trng_data SET consumed = TRUE WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func BenchmarkMarkConsumedLoop(b *testing.B) {
+	h, ids := setupBenchRows(b, 200)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := markConsumedLoop(h, ids); err != nil {
+			b.Fatalf("markConsumedLoop failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkMarkConsumedBatch(b *testing.B) {
+	h, ids := setupBenchRows(b, 200)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := h.markConsumed("trng_data", ids); err != nil {
+			b.Fatalf("markConsumed failed: %v", err)
+		}
+	}
+}
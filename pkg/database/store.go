@@ -0,0 +1,82 @@
+package database
+
+import "strings"
+
+// qPlaceholders returns a "?, ?, ..." placeholder list of length n, for
+// drivers (DuckDB, SQLite) that bind parameters positionally by "?" rather
+// than Postgres-style "$1, $2, ...".
+func qPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// intArgs converts a slice of row ids into []interface{} suitable for a
+// variadic sql.Exec/Query call alongside qPlaceholders.
+func intArgs(ids []int) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+// Store is the storage abstraction every entropy backend must satisfy. It
+// covers everything the TRNG store, the Fortuna store, and the stats/health
+// endpoints need, so callers can depend on Store instead of reaching into a
+// concrete driver such as DuckDBHandler.
+type Store interface {
+	// StoreTRNGHash persists a newly generated TRNG hash and trims the
+	// queue down to the configured size.
+	StoreTRNGHash(hash []byte, source string) error
+
+	// StoreFortunaData persists a Fortuna-generated chunk and trims the
+	// queue down to the configured size.
+	StoreFortunaData(data []byte, chunkSize int, amplificationFactor int) error
+
+	// StoreTRNGHealthFailure records a sample that tripped a continuous
+	// health test (e.g. SP 800-90B's Repetition Count or Adaptive
+	// Proportion tests) for post-mortem. The row is stored pre-consumed so
+	// it is never handed out as entropy.
+	StoreTRNGHealthFailure(sample []byte, source string) error
+
+	// GetTRNGHashes retrieves TRNG hashes with pagination and optionally
+	// marks the returned rows as consumed.
+	GetTRNGHashes(limit, offset int, consume bool) ([][]byte, error)
+
+	// GetFortunaData retrieves Fortuna-generated data with pagination and
+	// optionally marks the returned rows as consumed.
+	GetFortunaData(limit, offset int, consume bool) ([][]byte, error)
+
+	// ConsumeAtomic removes up to limit unconsumed TRNG hashes and returns
+	// them in one round trip, for keys-as-a-service workloads that must
+	// not retain consumed material.
+	ConsumeAtomic(limit int) ([][]byte, error)
+
+	// GetStats returns overall queue and pool statistics.
+	GetStats() (map[string]interface{}, error)
+
+	// GetSourceStats returns detailed hardware vs. software statistics.
+	GetSourceStats() (map[string]interface{}, error)
+
+	// UpdateQueueSizes changes the configured TRNG/Fortuna queue sizes and
+	// trims the queues if they now exceed the new limits.
+	UpdateQueueSizes(trngQueueSize, fortunaQueueSize int) error
+
+	// HealthCheck reports whether the backing store is reachable and
+	// accepting queries.
+	HealthCheck() bool
+
+	// Subscribe registers a listener for TRNG and Fortuna pool events. The
+	// returned CancelFunc must be called once the caller stops reading,
+	// e.g. when an HTTP/WebSocket client disconnects.
+	Subscribe(filter SubscriptionFilter) (<-chan Event, CancelFunc)
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// compile-time assertion that every driver satisfies Store.
+var (
+	_ Store = (*DuckDBHandler)(nil)
+	_ Store = (*PostgresHandler)(nil)
+	_ Store = (*SQLiteHandler)(nil)
+)
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// EventSource identifies which entropy source triggered a subscription event.
+type EventSource string
+
+const (
+	EventSourceHardware EventSource = "hardware"
+	EventSourceSoftware EventSource = "software"
+)
+
+// EventType identifies what happened to entropy material.
+type EventType string
+
+const (
+	EventStored   EventType = "stored"
+	EventConsumed EventType = "consumed"
+	EventTrimmed  EventType = "trimmed"
+)
+
+// Event describes a single change to the TRNG or Fortuna pools.
+type Event struct {
+	Pool      string      `json:"pool"` // "trng" or "fortuna"
+	Source    EventSource `json:"source,omitempty"`
+	Type      EventType   `json:"type"`
+	BatchSize int         `json:"batch_size"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// SubscriptionFilter narrows which events a subscriber receives. A zero
+// value for any field means "match anything" for that field.
+type SubscriptionFilter struct {
+	Source       EventSource
+	EventType    EventType
+	MinBatchSize int
+}
+
+func (f SubscriptionFilter) matches(e Event) bool {
+	if f.Source != "" && f.Source != e.Source {
+		return false
+	}
+	if f.EventType != "" && f.EventType != e.Type {
+		return false
+	}
+	if e.BatchSize < f.MinBatchSize {
+		return false
+	}
+	return true
+}
+
+// CancelFunc unregisters a subscription created by Subscribe.
+type CancelFunc func()
+
+type subscriber struct {
+	id     uint64
+	filter SubscriptionFilter
+	ch     chan Event
+}
+
+// eventHub fans out pool events to subscribers. Each Store driver
+// (DuckDBHandler, PostgresHandler, SQLiteHandler) holds one in its events
+// field so storing,
+// consuming, or trimming entropy can publish without the caller needing to
+// know whether anyone is listening.
+type eventHub struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[uint64]subscriber
+}
+
+// Subscribe registers a new listener for events matching filter. The
+// returned channel is closed once the returned CancelFunc is called. The
+// channel is buffered; a subscriber that falls behind has events dropped
+// rather than blocking publishers.
+func (h *eventHub) Subscribe(filter SubscriptionFilter) (<-chan Event, CancelFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subs == nil {
+		h.subs = make(map[uint64]subscriber)
+	}
+
+	h.nextID++
+	id := h.nextID
+	ch := make(chan Event, 16)
+	h.subs[id] = subscriber{id: id, filter: filter, ch: ch}
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if sub, ok := h.subs[id]; ok {
+			delete(h.subs, id)
+			close(sub.ch)
+		}
+	}
+
+	return ch, cancel
+}
+
+// publish fans event out to every subscriber whose filter matches it.
+func (h *eventHub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, sub := range h.subs {
+		if !sub.filter.matches(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			log.Printf("subscription %d is not keeping up, dropping event", sub.id)
+		}
+	}
+}
+
+// Subscribe registers a listener for TRNG and Fortuna pool events. Callers
+// should invoke the returned CancelFunc once they stop reading, e.g. when
+// an HTTP/WebSocket client disconnects.
+func (d *DuckDBHandler) Subscribe(filter SubscriptionFilter) (<-chan Event, CancelFunc) {
+	return d.events.Subscribe(filter)
+}